@@ -0,0 +1,42 @@
+package cucumberexpressions
+
+import "reflect"
+
+// Argument is one matched, not-yet-transformed placeholder from an
+// Expression.Match.
+type Argument struct {
+	value         *string
+	parameterType *ParameterType
+}
+
+// BuildArguments matches text against treeRegexp and pairs each capture
+// group with its corresponding parameterType, in order. It returns nil if
+// text doesn't match.
+func BuildArguments(treeRegexp *TreeRegexp, text string, parameterTypes []*ParameterType) []*Argument {
+	groupValues := treeRegexp.Match(text)
+	if groupValues == nil {
+		return nil
+	}
+	args := make([]*Argument, len(parameterTypes))
+	for i, parameterType := range parameterTypes {
+		var value *string
+		if i < len(groupValues) {
+			v := groupValues[i]
+			value = &v
+		}
+		args[i] = &Argument{value: value, parameterType: parameterType}
+	}
+	return args
+}
+
+// GetValue transforms the matched text via the Argument's ParameterType.
+// typeHint is accepted for callers (e.g. ExpressionRouter) that know the
+// target Go type, but is otherwise unused: a named ParameterType already
+// carries its own transform, and an anonymous one was already bound to a
+// type hint in CucumberExpression.Match.
+func (a *Argument) GetValue(typeHint reflect.Type) interface{} {
+	if a.value == nil {
+		return nil
+	}
+	return a.parameterType.transform(a.value)
+}
@@ -0,0 +1,181 @@
+package cucumberexpressions
+
+import (
+	"fmt"
+	"strings"
+)
+
+type nodeType int
+
+const (
+	textNode nodeType = iota
+	optionalNode
+	alternationNode
+	alternativeNode
+	parameterNode
+	expressionNode
+)
+
+type token struct {
+	text string
+}
+
+// astNode is a node in the tree CucumberExpression.rewriteNodeToRegex walks
+// to build the generated pattern.
+type astNode struct {
+	nodeType nodeType
+	token    token
+	nodes    []astNode
+}
+
+func newTextNode(text string) astNode {
+	return astNode{nodeType: textNode, token: token{text: text}}
+}
+
+// text returns a leaf node's raw text, or the concatenation of its
+// children's text for a non-leaf node.
+func (n astNode) text() string {
+	if n.nodeType == textNode || n.nodeType == parameterNode {
+		return n.token.text
+	}
+	var b strings.Builder
+	for _, child := range n.nodes {
+		b.WriteString(child.text())
+	}
+	return b.String()
+}
+
+// parse parses a cucumber expression into its astNode tree.
+func parse(expression string) (astNode, error) {
+	nodes, err := parseSequence(expression)
+	if err != nil {
+		return astNode{}, err
+	}
+	return astNode{nodeType: expressionNode, nodes: nodes}, nil
+}
+
+// parseSequence parses a run of text, {parameter}, (optional) and a/b
+// alternation nodes, stopping at the end of s.
+func parseSequence(s string) ([]astNode, error) {
+	var nodes []astNode
+	runes := []rune(s)
+	i := 0
+	for i < len(runes) {
+		switch {
+		case runes[i] == '{':
+			end, err := matchingBrace(runes, i, '{', '}')
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, astNode{nodeType: parameterNode, token: token{text: string(runes[i+1 : end])}})
+			i = end + 1
+		case runes[i] == ' ' || runes[i] == '\t':
+			start := i
+			for i < len(runes) && (runes[i] == ' ' || runes[i] == '\t') {
+				i++
+			}
+			nodes = append(nodes, newTextNode(string(runes[start:i])))
+		case runes[i] == '(' && !isConjunctionGroup(runes, i):
+			end, err := matchingBrace(runes, i, '(', ')')
+			if err != nil {
+				return nil, err
+			}
+			inner, err := parseSequence(string(runes[i+1 : end]))
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, astNode{nodeType: optionalNode, nodes: inner})
+			i = end + 1
+		default:
+			word, hasSlash, next, err := scanWord(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			if next == i {
+				// Defensive: an unexpected lone delimiter, treat it literally
+				// rather than looping forever.
+				nodes = append(nodes, newTextNode(string(runes[i])))
+				i++
+				continue
+			}
+			if hasSlash {
+				parts := strings.Split(word, "/")
+				alternatives := make([]astNode, len(parts))
+				for idx, part := range parts {
+					alternatives[idx] = astNode{nodeType: alternativeNode, nodes: []astNode{newTextNode(part)}}
+				}
+				nodes = append(nodes, astNode{nodeType: alternationNode, nodes: alternatives})
+			} else {
+				nodes = append(nodes, newTextNode(word))
+			}
+			i = next
+		}
+	}
+	return nodes, nil
+}
+
+// scanWord consumes a maximal run of non-whitespace, non-"{" text starting
+// at i, honoring backslash escapes and tracking whether a top-level "/" was
+// seen. A "(...)" span is included verbatim (rather than ending the word)
+// when it is a conjunction group, e.g. "(a & b)", so that alternatives like
+// "x/(a & b)" parse as a single alternation rather than an optional.
+func scanWord(runes []rune, i int) (word string, hasSlash bool, next int, err error) {
+	var buf strings.Builder
+	for i < len(runes) {
+		r := runes[i]
+		if r == '{' || r == ' ' || r == '\t' {
+			break
+		}
+		if r == '(' {
+			if !isConjunctionGroup(runes, i) {
+				break
+			}
+			end, perr := matchingBrace(runes, i, '(', ')')
+			if perr != nil {
+				return "", false, 0, perr
+			}
+			buf.WriteString(string(runes[i : end+1]))
+			i = end + 1
+			continue
+		}
+		if r == '\\' && i+1 < len(runes) {
+			buf.WriteRune(runes[i+1])
+			i += 2
+			continue
+		}
+		if r == '/' {
+			hasSlash = true
+		}
+		buf.WriteRune(r)
+		i++
+	}
+	return buf.String(), hasSlash, i, nil
+}
+
+// isConjunctionGroup reports whether the "(...)" span starting at i is a
+// ruleguard-style "(a & b)" conjunction rather than an ordinary optional.
+func isConjunctionGroup(runes []rune, i int) bool {
+	end, err := matchingBrace(runes, i, '(', ')')
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(runes[i+1:end]), " & ")
+}
+
+// matchingBrace returns the index of the close rune matching the open rune
+// at start, honoring nesting.
+func matchingBrace(runes []rune, start int, open, close rune) (int, error) {
+	depth := 0
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return -1, NewCucumberExpressionError(fmt.Sprintf("Missing closing %q", string(close)))
+}
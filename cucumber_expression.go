@@ -13,6 +13,16 @@ const parameterTypesCanNotBeOptional = "Parameter types cannot be optional: %s"
 const alternativeMayNotExclusivelyContainOptionals = "Alternative may not exclusively contain optionals: %s"
 const couldNotRewrite = "Could not rewrite %s"
 const optionalMayNotBeEmpty = "Optional may not be empty: %s"
+const parameterTypesCanNotBeRepeatedInOptional = "Parameter types cannot be repeated inside an optional: %s"
+const parameterTypesCanNotBeRepeatedInAlternative = "Parameter types cannot be repeated inside an alternative: %s"
+const negationMayNotBeEmpty = "Negation (!) may not be empty: %s"
+const negationCanNotBeCombinedWithParameterTypes = "Negation (!...) cannot be combined with parameter types: %s"
+const negationOfMultiCharacterLiteralRequiresLookahead = "Negating %q is not supported, with any RegexEngine; only a single character can be negated: %s"
+const conjunctionNotImplementedUnderRE2 = "Conjunction (%s) is not implemented as true AND, with any RegexEngine; it only accepts operands that are all identical to each other (equivalent to matching that one literal): %s"
+const invalidRepetitionModifier = "Invalid repetition modifier %q, expected \"name;sep;\""
+const inlineRegexpMayNotBeEmpty = "Inline regexp may not be empty: %s"
+const inlineRegexpMayNotBeAnonymous = "Inline regexp requires a parameter name, e.g. {name:%s}: %s"
+const invalidInlineRegexp = "Invalid inline regexp %q in %s: %s"
 
 var escapeRegexp = regexp.MustCompile(`([\\^\[({$.|?*+})\]])`)
 
@@ -21,10 +31,22 @@ type CucumberExpression struct {
 	parameterTypes        []*ParameterType
 	treeRegexp            *TreeRegexp
 	parameterTypeRegistry *ParameterTypeRegistry
+	regexEngine           RegexEngine
 }
 
 func NewCucumberExpression(expression string, parameterTypeRegistry *ParameterTypeRegistry) (Expression, error) {
-	result := &CucumberExpression{source: expression, parameterTypeRegistry: parameterTypeRegistry}
+	return NewCucumberExpressionWithEngine(expression, parameterTypeRegistry, parameterTypeRegistry.regexEngine())
+}
+
+// NewCucumberExpressionWithEngine is like NewCucumberExpression, but compiles
+// the generated pattern with the given RegexEngine instead of the registry's
+// default. This lets callers opt individual expressions into a regex engine
+// with a different feature set, e.g. one supporting lookaround.
+func NewCucumberExpressionWithEngine(expression string, parameterTypeRegistry *ParameterTypeRegistry, regexEngine RegexEngine) (Expression, error) {
+	if regexEngine == nil {
+		regexEngine = defaultRegexEngine
+	}
+	result := &CucumberExpression{source: expression, parameterTypeRegistry: parameterTypeRegistry, regexEngine: regexEngine}
 
 	ast, err := parse(expression)
 	if err != nil {
@@ -35,7 +57,11 @@ func NewCucumberExpression(expression string, parameterTypeRegistry *ParameterTy
 	if err != nil {
 		return nil, err
 	}
-	result.treeRegexp = NewTreeRegexp(regexp.MustCompile(pattern))
+	compiled, err := regexEngine.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	result.treeRegexp = NewTreeRegexpWithCompiledRegexp(compiled)
 	return result, nil
 }
 
@@ -71,15 +97,33 @@ func (c *CucumberExpression) rewriteOptional(node astNode) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	err = c.assertNoRepetitions(node, parameterTypesCanNotBeRepeatedInOptional)
+	if err != nil {
+		return "", err
+	}
 	return c.rewriteNodesToRegex(node.nodes, "", "(?:", ")?")
 }
 
 func (c *CucumberExpression) rewriteAlternation(node astNode) (string, error) {
 	// Make sure the alternative parts aren't empty and don't contain parameter types
-	for _, alternative := range node.nodes {
+	extended := c.parameterTypeRegistry.extendedAlternationsEnabled()
+	branches := make([]string, len(node.nodes))
+	for i, alternative := range node.nodes {
 		if len(alternative.nodes) == 0 {
 			return "", NewCucumberExpressionError(fmt.Sprintf(alternativesMayNotBeEmpty, c.source))
 		}
+
+		if extended {
+			branch, handled, err := c.rewriteExtendedAlternative(alternative)
+			if err != nil {
+				return "", err
+			}
+			if handled {
+				branches[i] = branch
+				continue
+			}
+		}
+
 		err := c.assertNoParameters(alternative, parameterTypesCanNotBeAlternative)
 		if err != nil {
 			return "", err
@@ -88,8 +132,78 @@ func (c *CucumberExpression) rewriteAlternation(node astNode) (string, error) {
 		if err != nil {
 			return "", err
 		}
+		err = c.assertNoRepetitions(alternative, parameterTypesCanNotBeRepeatedInAlternative)
+		if err != nil {
+			return "", err
+		}
+		branch, err := c.rewriteAlternative(alternative)
+		if err != nil {
+			return "", err
+		}
+		branches[i] = branch
 	}
-	return c.rewriteNodesToRegex(node.nodes, "|", "(?:", ")")
+	return fmt.Sprintf("(?:%s)", strings.Join(branches, "|")), nil
+}
+
+// rewriteExtendedAlternative recognizes the opt-in "!word" negation and
+// "(a & b)" conjunction alternatives (ParameterTypeRegistry.extendedAlternationsEnabled).
+// handled is false when the alternative is an ordinary alternative, which the
+// caller should rewrite with the usual checks instead.
+func (c *CucumberExpression) rewriteExtendedAlternative(alternative astNode) (branch string, handled bool, err error) {
+	if len(alternative.nodes) > 1 && alternative.nodes[0].nodeType == textNode && strings.HasPrefix(alternative.nodes[0].text(), "!") {
+		return "", false, NewCucumberExpressionError(fmt.Sprintf(negationCanNotBeCombinedWithParameterTypes, c.source))
+	}
+
+	literal, isLiteral := c.alternativeLiteralText(alternative)
+	if !isLiteral {
+		return "", false, nil
+	}
+	return rewriteExtendedLiteral(literal, c.processEscapes, c.source)
+}
+
+// rewriteExtendedLiteral implements the literal-only half of
+// rewriteExtendedAlternative as a pure function, so it's testable without an
+// astNode. escape is CucumberExpression.processEscapes.
+//
+// Negation of a single character becomes an RE2 character-class complement.
+// Conjunction is NOT implemented as true AND over distinct alternatives (RE2
+// has no lookahead to express that): the "(a & b)" form only succeeds in the
+// degenerate case where every operand is the same literal, which is
+// expressible as that one literal. Anything else is rejected rather than
+// silently matching something weaker than what the user asked for.
+func rewriteExtendedLiteral(literal string, escape func(string) string, source string) (branch string, handled bool, err error) {
+	if strings.HasPrefix(literal, "!") {
+		negated := literal[1:]
+		if negated == "" {
+			return "", false, NewCucumberExpressionError(fmt.Sprintf(negationMayNotBeEmpty, source))
+		}
+		if len([]rune(negated)) > 1 {
+			return "", false, NewCucumberExpressionError(fmt.Sprintf(negationOfMultiCharacterLiteralRequiresLookahead, negated, source))
+		}
+		return fmt.Sprintf("[^%s]", escape(negated)), true, nil
+	}
+
+	if strings.HasPrefix(literal, "(") && strings.HasSuffix(literal, ")") && strings.Contains(literal, " & ") {
+		operands := strings.Split(literal[1:len(literal)-1], " & ")
+		first := strings.TrimSpace(operands[0])
+		for _, operand := range operands[1:] {
+			if strings.TrimSpace(operand) != first {
+				return "", false, NewCucumberExpressionError(fmt.Sprintf(conjunctionNotImplementedUnderRE2, literal, source))
+			}
+		}
+		return fmt.Sprintf("(?:%s)", escape(first)), true, nil
+	}
+
+	return "", false, nil
+}
+
+// alternativeLiteralText returns an alternative's text when it consists of a
+// single text node, and false otherwise (e.g. it contains a parameter type).
+func (c *CucumberExpression) alternativeLiteralText(node astNode) (string, bool) {
+	if len(node.nodes) != 1 || node.nodes[0].nodeType != textNode {
+		return "", false
+	}
+	return node.nodes[0].text(), true
 }
 
 func (c *CucumberExpression) rewriteAlternative(node astNode) (string, error) {
@@ -110,7 +224,50 @@ func (c *CucumberExpression) rewriteParameter(node astNode) (string, error) {
 		return fmt.Sprintf("(%s)", strings.Join(captureGroups, "|"))
 	}
 
-	typeName := node.text()
+	text := node.text()
+
+	// A colon before any ";" starts an inline constraint, so the "+" in
+	// {id:[0-9]+} belongs to the pattern rather than being a repetition modifier.
+	name, inlinePattern, hasInline := splitInlineConstraint(text)
+	hasInline = hasInline && !strings.Contains(name, ";")
+	if !hasInline {
+		repeatedName, separatorPattern, isRepetition, err := parseRepetitionModifier(text)
+		if err != nil {
+			return "", err
+		}
+		if isRepetition {
+			return c.rewriteRepeatedParameter(repeatedName, separatorPattern)
+		}
+	}
+
+	if hasInline {
+		if inlinePattern == "" {
+			return "", NewCucumberExpressionError(fmt.Sprintf(inlineRegexpMayNotBeEmpty, c.source))
+		}
+		if name == "" {
+			return "", NewCucumberExpressionError(fmt.Sprintf(inlineRegexpMayNotBeAnonymous, inlinePattern, c.source))
+		}
+		inlineRegexp, err := regexp.Compile(inlinePattern)
+		if err != nil {
+			return "", NewCucumberExpressionError(fmt.Sprintf(invalidInlineRegexp, inlinePattern, c.source, err.Error()))
+		}
+		err = CheckParameterTypeName(name)
+		if err != nil {
+			return "", err
+		}
+		parameterType := c.parameterTypeRegistry.LookupByTypeName(name)
+		if parameterType == nil {
+			parameterType = newAnonymousParameterType([]*regexp.Regexp{inlineRegexp})
+		} else {
+			withInlineRegexp := *parameterType
+			withInlineRegexp.regexps = []*regexp.Regexp{inlineRegexp}
+			parameterType = &withInlineRegexp
+		}
+		c.parameterTypes = append(c.parameterTypes, parameterType)
+		return buildCaptureRegexp(parameterType.regexps), nil
+	}
+
+	typeName := text
 	err := CheckParameterTypeName(typeName)
 	if err != nil {
 		return "", err
@@ -124,6 +281,84 @@ func (c *CucumberExpression) rewriteParameter(node astNode) (string, error) {
 	return buildCaptureRegexp(parameterType.regexps), nil
 }
 
+// splitInlineConstraint splits a parameter's raw text on the first colon to
+// support router-style inline regex constraints such as {id:[0-9]+}. hasInline
+// is false when there is no colon, in which case text is an ordinary type name.
+func splitInlineConstraint(text string) (name string, pattern string, hasInline bool) {
+	i := strings.Index(text, ":")
+	if i < 0 {
+		return text, "", false
+	}
+	return text[:i], text[i+1:], true
+}
+
+// newAnonymousParameterType builds an ephemeral, unregistered ParameterType
+// for an inline regex constraint whose name does not match a registered
+// parameter type. It defers conversion to defaultTransformer using the
+// caller-supplied type hint. A bare {} does not produce one: the registry
+// defines no parameter type named "", so {} is an undefined parameter type.
+func newAnonymousParameterType(regexps []*regexp.Regexp) *ParameterType {
+	return &ParameterType{regexps: regexps}
+}
+
+// rewriteRepeatedParameter builds the regex for a repetition modifier such as
+// {int+} or {int, }: a delimited sequence of one or more matches of name's
+// parameter type, captured as a single group. The group is not split into
+// elements: Argument.GetValue passes the whole run, separators included, to
+// that parameter type's transform, and no []T is produced.
+func (c *CucumberExpression) rewriteRepeatedParameter(name string, separatorPattern string) (string, error) {
+	err := CheckParameterTypeName(name)
+	if err != nil {
+		return "", err
+	}
+	parameterType := c.parameterTypeRegistry.LookupByTypeName(name)
+	if parameterType == nil {
+		return "", NewUndefinedParameterTypeError(name)
+	}
+	c.parameterTypes = append(c.parameterTypes, parameterType)
+	inner := buildNonCapturingRegexp(parameterType.regexps)
+	return fmt.Sprintf("(%s(?:%s%s)*)", inner, separatorPattern, inner), nil
+}
+
+// buildNonCapturingRegexp is buildCaptureRegexp without the outer capturing
+// parens, for embedding a parameter type's pattern inside a larger group such
+// as a repetition.
+func buildNonCapturingRegexp(regexps []*regexp.Regexp) string {
+	if len(regexps) == 1 {
+		return fmt.Sprintf("(?:%s)", regexps[0].String())
+	}
+
+	groups := make([]string, len(regexps))
+	for i, r := range regexps {
+		groups[i] = fmt.Sprintf("(?:%s)", r.String())
+	}
+	return fmt.Sprintf("(?:%s)", strings.Join(groups, "|"))
+}
+
+const defaultRepetitionSeparatorPattern = `,\s*`
+
+// parseRepetitionModifier recognizes the repetition modifiers on a
+// parameter's raw text: a trailing "+" or ", " for the default ",\s*"
+// separator, or "name;sep;" for a custom literal separator (e.g. "int;;" for
+// an empty one). A malformed "name;..." that isn't exactly "name;sep;" is
+// reported as err rather than falling through as an ordinary type name.
+func parseRepetitionModifier(text string) (name string, separatorPattern string, ok bool, err error) {
+	if strings.HasSuffix(text, "+") {
+		return strings.TrimSuffix(text, "+"), defaultRepetitionSeparatorPattern, true, nil
+	}
+	if strings.HasSuffix(text, ", ") {
+		return strings.TrimSuffix(text, ", "), defaultRepetitionSeparatorPattern, true, nil
+	}
+	if strings.Contains(text, ";") {
+		parts := strings.SplitN(text, ";", 3)
+		if len(parts) == 3 && parts[2] == "" {
+			return parts[0], regexp.QuoteMeta(parts[1]), true, nil
+		}
+		return "", "", false, NewCucumberExpressionError(fmt.Sprintf(invalidRepetitionModifier, text))
+	}
+	return "", "", false, nil
+}
+
 func (c *CucumberExpression) rewriteExpression(node astNode) (string, error) {
 	return c.rewriteNodesToRegex(node.nodes, "", "^", "$")
 }
@@ -154,6 +389,20 @@ func (c *CucumberExpression) assertNotEmpty(node astNode, message string) error
 	return NewCucumberExpressionError(fmt.Sprintf(message, c.source))
 }
 
+// assertNoRepetitions rejects {name+}/{name, }/{name;sep;} repetitions
+// nested under an optional or an alternative, mirroring assertNoParameters.
+func (c *CucumberExpression) assertNoRepetitions(node astNode, message string) error {
+	for _, child := range node.nodes {
+		if child.nodeType != parameterNode {
+			continue
+		}
+		if _, _, ok, _ := parseRepetitionModifier(child.text()); ok {
+			return NewCucumberExpressionError(fmt.Sprintf(message, c.source))
+		}
+	}
+	return nil
+}
+
 func (c *CucumberExpression) assertNoParameters(node astNode, message string) error {
 	for _, node := range node.nodes {
 		if node.nodeType == parameterNode {
@@ -195,6 +444,12 @@ func (c *CucumberExpression) Source() string {
 	return c.source
 }
 
+// IsLiteral reports whether this expression has no parameters, so an
+// ExpressionRouter can try literal expressions before parameterized ones.
+func (c *CucumberExpression) IsLiteral() bool {
+	return len(c.parameterTypes) == 0
+}
+
 func (c *CucumberExpression) objectMapperTransformer(typeHint reflect.Type) func(args ...*string) interface{} {
 	return func(args ...*string) interface{} {
 		i, err := c.parameterTypeRegistry.defaultTransformer.Transform(*args[0], typeHint)
@@ -0,0 +1,38 @@
+package cucumberexpressions
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// CucumberExpressionError is returned for any problem parsing or compiling a
+// CucumberExpression, or registering/looking up a ParameterType.
+type CucumberExpressionError struct {
+	msg string
+}
+
+func (e *CucumberExpressionError) Error() string {
+	return e.msg
+}
+
+// NewCucumberExpressionError builds a CucumberExpressionError with message.
+func NewCucumberExpressionError(message string) error {
+	return &CucumberExpressionError{msg: message}
+}
+
+// NewUndefinedParameterTypeError reports a {typeName} with no matching
+// registered ParameterType.
+func NewUndefinedParameterTypeError(typeName string) error {
+	return NewCucumberExpressionError(fmt.Sprintf("Undefined parameter type {%s}", typeName))
+}
+
+var illegalParameterTypeNameRegexp = regexp.MustCompile(`([\[\]()$.|?*+\\])`)
+
+// CheckParameterTypeName rejects parameter type names containing characters
+// that would be ambiguous inside a generated regexp.
+func CheckParameterTypeName(name string) error {
+	if illegalParameterTypeNameRegexp.MatchString(name) {
+		return NewCucumberExpressionError(fmt.Sprintf("Illegal character in parameter name {%s}", name))
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package cucumberexpressions
+
+import (
+	"reflect"
+	"regexp"
+)
+
+// Expression is anything that can match a piece of step text and produce
+// Arguments for it, e.g. a CucumberExpression.
+type Expression interface {
+	Match(text string, typeHints ...reflect.Type) ([]*Argument, error)
+	Regexp() *regexp.Regexp
+	Source() string
+
+	// IsLiteral reports whether the expression has no parameters, so an
+	// ExpressionRouter can try literal expressions before parameterized ones
+	// without reaching into a concrete implementation's private fields.
+	IsLiteral() bool
+}
@@ -0,0 +1,164 @@
+package cucumberexpressions
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Route is a single registered Expression/handler pair.
+type Route struct {
+	Expression Expression
+	Handler    interface{}
+}
+
+// ExpressionRouter registers many Expressions against handler callbacks and
+// dispatches an incoming step text to the best match. Literal expressions
+// (no parameters) are tried before parameterized ones.
+type ExpressionRouter struct {
+	mu      sync.RWMutex
+	literal []*Route
+	params  []*Route
+}
+
+// NewExpressionRouter returns an empty ExpressionRouter.
+func NewExpressionRouter() *ExpressionRouter {
+	return &ExpressionRouter{}
+}
+
+// Register adds expr to the router, dispatching to handler on a match.
+func (r *ExpressionRouter) Register(expr Expression, handler interface{}) error {
+	if expr == nil {
+		return NewCucumberExpressionError("expression must not be nil")
+	}
+	if handler == nil {
+		return NewCucumberExpressionError("handler must not be nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	route := &Route{Expression: expr, Handler: handler}
+	if expr.IsLiteral() {
+		r.literal = append(r.literal, route)
+	} else {
+		r.params = append(r.params, route)
+	}
+	return nil
+}
+
+// MustRegister is like Register but panics on error, for use at
+// initialization time.
+func (r *ExpressionRouter) MustRegister(expr Expression, handler interface{}) {
+	if err := r.Register(expr, handler); err != nil {
+		panic(err)
+	}
+}
+
+// Match finds the Route whose Expression matches text, trying literal
+// expressions before parameterized ones. A matching literal expression wins
+// outright; it returns an error if more than one literal expression, or
+// (with no literal match) more than one parameterized expression, matches
+// (an ambiguous match). If no expression matches, it returns a nil Route and
+// a nil error.
+func (r *ExpressionRouter) Match(text string) (*Route, []*Argument, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if route, args, err := r.matchIn(r.literal, text); route != nil || err != nil {
+		return route, args, err
+	}
+	return r.matchIn(r.params, text)
+}
+
+func (r *ExpressionRouter) matchIn(routes []*Route, text string) (*Route, []*Argument, error) {
+	var matched *Route
+	var matchedArgs []*Argument
+	var ambiguous []*Route
+
+	for _, route := range routes {
+		// Pass the handler's parameter types as type hints, so anonymous
+		// parameters are transformed to what the handler takes.
+		var typeHints []reflect.Type
+		if handlerType := reflect.TypeOf(route.Handler); handlerType.Kind() == reflect.Func {
+			for i := 0; i < handlerType.NumIn(); i++ {
+				typeHints = append(typeHints, handlerType.In(i))
+			}
+		}
+		args, err := route.Expression.Match(text, typeHints...)
+		if err != nil {
+			return nil, nil, err
+		}
+		if args == nil {
+			continue
+		}
+		if matched == nil {
+			matched = route
+			matchedArgs = args
+		} else {
+			ambiguous = append(ambiguous, route)
+		}
+	}
+
+	if len(ambiguous) > 0 {
+		sources := make([]string, 0, len(ambiguous)+1)
+		sources = append(sources, matched.Expression.Source())
+		for _, route := range ambiguous {
+			sources = append(sources, route.Expression.Source())
+		}
+		return nil, nil, NewCucumberExpressionError(fmt.Sprintf("%q matches more than one expression: %v", text, sources))
+	}
+	return matched, matchedArgs, nil
+}
+
+// Dispatch finds the Route matching text and invokes its handler via
+// reflection. The handler must be a func; its return values, if any, are
+// returned as []interface{}.
+func (r *ExpressionRouter) Dispatch(text string) ([]interface{}, error) {
+	route, args, err := r.Match(text)
+	if err != nil {
+		return nil, err
+	}
+	if route == nil {
+		return nil, NewCucumberExpressionError(fmt.Sprintf("no expression matches: %s", text))
+	}
+
+	handlerValue := reflect.ValueOf(route.Handler)
+	handlerType := handlerValue.Type()
+	if handlerType.Kind() != reflect.Func {
+		return nil, NewCucumberExpressionError("handler must be a func")
+	}
+	if handlerType.NumIn() != len(args) {
+		return nil, NewCucumberExpressionError(fmt.Sprintf("handler expects %d arguments, but expression produced %d", handlerType.NumIn(), len(args)))
+	}
+
+	in, err := convertArguments(args, handlerType)
+	if err != nil {
+		return nil, err
+	}
+
+	out := handlerValue.Call(in)
+	result := make([]interface{}, len(out))
+	for i, v := range out {
+		result[i] = v.Interface()
+	}
+	return result, nil
+}
+
+// convertArguments transforms each matched Argument into the handler's
+// corresponding parameter type.
+func convertArguments(args []*Argument, handlerType reflect.Type) (in []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			in = nil
+			err = NewCucumberExpressionError(fmt.Sprintf("could not convert argument: %v", r))
+		}
+	}()
+
+	in = make([]reflect.Value, len(args))
+	for i, arg := range args {
+		value := arg.GetValue(handlerType.In(i))
+		in[i] = reflect.ValueOf(value)
+	}
+	return in, nil
+}
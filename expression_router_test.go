@@ -0,0 +1,83 @@
+package cucumberexpressions
+
+import "testing"
+
+func TestExpressionRouterDispatch(t *testing.T) {
+	registry := NewParameterTypeRegistry()
+	router := NewExpressionRouter()
+
+	cukes, err := NewCucumberExpression("I have {int} cukes", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got int
+	router.MustRegister(cukes, func(n int) string {
+		got = n
+		return "counted cukes"
+	})
+
+	hello, err := NewCucumberExpression("hello, world!", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.MustRegister(hello, func() string { return "greeted" })
+
+	out, err := router.Dispatch("I have 5 cukes")
+	if err != nil {
+		t.Fatalf("Dispatch returned error: %v", err)
+	}
+	if got != 5 {
+		t.Errorf("handler received %d, want 5", got)
+	}
+	if len(out) != 1 || out[0] != "counted cukes" {
+		t.Errorf("Dispatch = %v, want [\"counted cukes\"]", out)
+	}
+
+	// Text that only the literal expression matches dispatches to the
+	// literal expression's handler.
+	out, err = router.Dispatch("hello, world!")
+	if err != nil {
+		t.Fatalf("Dispatch returned error: %v", err)
+	}
+	if len(out) != 1 || out[0] != "greeted" {
+		t.Errorf("Dispatch = %v, want [\"greeted\"]", out)
+	}
+
+	if _, err := router.Dispatch("no expression matches this"); err == nil {
+		t.Error("Dispatch with no matching expression returned no error")
+	}
+}
+
+func TestExpressionRouterDispatchAmbiguous(t *testing.T) {
+	registry := NewParameterTypeRegistry()
+	router := NewExpressionRouter()
+
+	a, _ := NewCucumberExpression("I have {int} cukes", registry)
+	b, _ := NewCucumberExpression("I have {word} cukes", registry)
+	router.MustRegister(a, func(n int) string { return "int" })
+	router.MustRegister(b, func(s string) string { return "word" })
+
+	if _, err := router.Dispatch("I have 5 cukes"); err == nil {
+		t.Error("Dispatch with two matching parameterized expressions returned no error")
+	}
+}
+
+func TestExpressionRouterIsLiteralClassifiesWithoutReachingIntoConcreteType(t *testing.T) {
+	registry := NewParameterTypeRegistry()
+
+	literal, err := NewCucumberExpression("I have cukes", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !literal.IsLiteral() {
+		t.Error("IsLiteral() = false for an expression with no parameters, want true")
+	}
+
+	parameterized, err := NewCucumberExpression("I have {int} cukes", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parameterized.IsLiteral() {
+		t.Error("IsLiteral() = true for an expression with a parameter, want false")
+	}
+}
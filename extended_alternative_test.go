@@ -0,0 +1,105 @@
+package cucumberexpressions
+
+import "testing"
+
+func escapeForTest(s string) string {
+	return escapeRegexp.ReplaceAllString(s, `\$1`)
+}
+
+func TestRewriteExtendedLiteralNegation(t *testing.T) {
+	branch, handled, err := rewriteExtendedLiteral("!x", escapeForTest, "expr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !handled {
+		t.Fatal("handled = false, want true")
+	}
+	if want := "[^x]"; branch != want {
+		t.Errorf("branch = %q, want %q", branch, want)
+	}
+}
+
+func TestRewriteExtendedLiteralNegationEmpty(t *testing.T) {
+	if _, _, err := rewriteExtendedLiteral("!", escapeForTest, "expr"); err == nil {
+		t.Fatal("expected error for empty negation")
+	}
+}
+
+func TestRewriteExtendedLiteralNegationMultiCharacter(t *testing.T) {
+	if _, _, err := rewriteExtendedLiteral("!word", escapeForTest, "expr"); err == nil {
+		t.Fatal("expected error negating a multi-character literal under RE2")
+	}
+}
+
+func TestRewriteExtendedLiteralConjunctionIdenticalOperands(t *testing.T) {
+	branch, handled, err := rewriteExtendedLiteral("(a & a)", escapeForTest, "expr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !handled {
+		t.Fatal("handled = false, want true")
+	}
+	if want := "(?:a)"; branch != want {
+		t.Errorf("branch = %q, want %q", branch, want)
+	}
+}
+
+func TestRewriteExtendedLiteralConjunctionDistinctOperandsRejected(t *testing.T) {
+	if _, _, err := rewriteExtendedLiteral("(a & b)", escapeForTest, "expr"); err == nil {
+		t.Fatal("expected error: (a & b) is not true conjunction under RE2")
+	}
+}
+
+func TestRewriteExtendedLiteralOrdinaryLiteralUnhandled(t *testing.T) {
+	_, handled, err := rewriteExtendedLiteral("plain", escapeForTest, "expr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handled {
+		t.Fatal("handled = true, want false for an ordinary literal")
+	}
+}
+
+// TestNegationEndToEnd pins down that negation, end to end through
+// CucumberExpression, only works for a single negated character under RE2 —
+// not arbitrary negated sub-patterns.
+func TestNegationEndToEnd(t *testing.T) {
+	registry := NewParameterTypeRegistry()
+	registry.EnableExtendedAlternations()
+
+	e, err := NewCucumberExpression("I do !x/y like it", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if args, err := e.Match("I do y like it"); err != nil || args == nil {
+		t.Fatalf("Match(%q) = (%v, %v), want a match", "I do y like it", args, err)
+	}
+	if args, _ := e.Match("I do x like it"); args != nil {
+		t.Errorf("Match(%q) = %v, want no match for the negated character", "I do x like it", args)
+	}
+
+	if _, err := NewCucumberExpression("I do !word/y like it", registry); err == nil {
+		t.Fatal("expected error negating a multi-character literal under RE2")
+	}
+}
+
+// TestConjunctionEndToEnd pins down that conjunction, end to end through
+// CucumberExpression, is only accepted when both operands are identical —
+// it is not true AND over distinct sub-patterns.
+func TestConjunctionEndToEnd(t *testing.T) {
+	registry := NewParameterTypeRegistry()
+	registry.EnableExtendedAlternations()
+
+	e, err := NewCucumberExpression("I have (a & a)/b cukes", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args, err := e.Match("I have a cukes"); err != nil || args == nil {
+		t.Fatalf("Match(%q) = (%v, %v), want a match", "I have a cukes", args, err)
+	}
+
+	if _, err := NewCucumberExpression("I have (a & b)/c cukes", registry); err == nil {
+		t.Fatal("expected error: (a & b) is not true conjunction under RE2")
+	}
+}
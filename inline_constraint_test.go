@@ -0,0 +1,56 @@
+package cucumberexpressions
+
+import "testing"
+
+func TestSplitInlineConstraint(t *testing.T) {
+	tests := []struct {
+		text      string
+		name      string
+		pattern   string
+		hasInline bool
+	}{
+		{text: "int", name: "int", hasInline: false},
+		{text: "id:[0-9]+", name: "id", pattern: "[0-9]+", hasInline: true},
+		{text: ":[0-9]+", name: "", pattern: "[0-9]+", hasInline: true},
+		{text: "id:", name: "id", pattern: "", hasInline: true},
+	}
+
+	for _, tt := range tests {
+		name, pattern, hasInline := splitInlineConstraint(tt.text)
+		if name != tt.name || pattern != tt.pattern || hasInline != tt.hasInline {
+			t.Errorf("splitInlineConstraint(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.text, name, pattern, hasInline, tt.name, tt.pattern, tt.hasInline)
+		}
+	}
+}
+
+func TestInlineConstraintOverridesWithoutMutatingRegistry(t *testing.T) {
+	registry := NewParameterTypeRegistry()
+
+	e, err := NewCucumberExpression("I have {int:[0-9][0-9]} cukes", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if args, _ := e.Match("I have 5 cukes"); args != nil {
+		t.Errorf("Match(%q) = %v, want no match under the 2-digit inline override", "I have 5 cukes", args)
+	}
+	args, err := e.Match("I have 42 cukes")
+	if err != nil || args == nil {
+		t.Fatalf("Match(%q) = (%v, %v), want a match", "I have 42 cukes", args, err)
+	}
+	if got := args[0].GetValue(nil); got != 42 {
+		t.Errorf("got %v, want 42", got)
+	}
+
+	// The registry's own "int" parameter type must be unaffected by the
+	// inline override above.
+	other, err := NewCucumberExpression("I have {int} apples", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err = other.Match("I have 5 apples")
+	if err != nil || args == nil {
+		t.Fatalf("registry's \"int\" parameter type was mutated by the inline override: (%v, %v)", args, err)
+	}
+}
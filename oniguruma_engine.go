@@ -0,0 +1,35 @@
+//go:build oniguruma
+
+package cucumberexpressions
+
+import rubex "github.com/go-enry/go-oniguruma"
+
+// onigurumaEngine is an opt-in RegexEngine backed by Oniguruma, enabled with
+// the "oniguruma" build tag.
+type onigurumaEngine struct{}
+
+// NewOnigurumaEngine returns a RegexEngine backed by Oniguruma.
+func NewOnigurumaEngine() RegexEngine {
+	return onigurumaEngine{}
+}
+
+func (onigurumaEngine) Compile(pattern string) (CompiledRegexp, error) {
+	re, err := rubex.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return onigurumaCompiledRegexp{re}, nil
+}
+
+// onigurumaCompiledRegexp adapts *rubex.Regexp to CompiledRegexp.
+type onigurumaCompiledRegexp struct {
+	re *rubex.Regexp
+}
+
+func (c onigurumaCompiledRegexp) FindSubmatch(s string) []string {
+	return c.re.FindStringSubmatch(s)
+}
+
+func (c onigurumaCompiledRegexp) String() string {
+	return c.re.String()
+}
@@ -0,0 +1,35 @@
+package cucumberexpressions
+
+import (
+	"reflect"
+	"regexp"
+)
+
+// ParameterType maps a {name} placeholder to the regexp(s) it matches and the
+// function used to transform the matched text into a Go value.
+type ParameterType struct {
+	name      string
+	regexps   []*regexp.Regexp
+	transform func(args ...*string) interface{}
+}
+
+// NewParameterType registers a named parameter type matching any of regexps,
+// converting a match via transform.
+func NewParameterType(name string, regexps []*regexp.Regexp, transform func(args ...*string) interface{}) *ParameterType {
+	return &ParameterType{name: name, regexps: regexps, transform: transform}
+}
+
+// isAnonymous reports whether this ParameterType still needs a transform
+// bound to a caller-supplied type hint, as produced by an inline constraint
+// with no matching registered name.
+func (p *ParameterType) isAnonymous() bool {
+	return p.transform == nil
+}
+
+// deAnonymize returns a copy of p with transform bound to the given type
+// hint, for an anonymous ParameterType encountered during Match.
+func (p *ParameterType) deAnonymize(typeHint reflect.Type, transform func(args ...*string) interface{}) (*ParameterType, error) {
+	clone := *p
+	clone.transform = transform
+	return &clone, nil
+}
@@ -0,0 +1,72 @@
+package cucumberexpressions
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// ParameterTypeRegistry holds the ParameterTypes a CucumberExpression can
+// reference by name, plus the defaults it compiles and transforms with.
+type ParameterTypeRegistry struct {
+	parameterTypesByName map[string]*ParameterType
+	defaultTransformer   Transformer
+	defaultEngine        RegexEngine
+	extendedAlternations bool
+}
+
+// NewParameterTypeRegistry returns a registry seeded with the built-in "int"
+// and "word" parameter types.
+func NewParameterTypeRegistry() *ParameterTypeRegistry {
+	r := &ParameterTypeRegistry{
+		parameterTypesByName: make(map[string]*ParameterType),
+		defaultTransformer:   defaultTransformerImpl{},
+		defaultEngine:        defaultRegexEngine,
+	}
+	r.DefineParameterType(NewParameterType("int", []*regexp.Regexp{regexp.MustCompile(`-?\d+`)},
+		func(args ...*string) interface{} {
+			n, err := strconv.ParseInt(*args[0], 10, 64)
+			if err != nil {
+				panic(err)
+			}
+			return int(n)
+		}))
+	r.DefineParameterType(NewParameterType("word", []*regexp.Regexp{regexp.MustCompile(`[^\s]+`)},
+		func(args ...*string) interface{} {
+			return *args[0]
+		}))
+	return r
+}
+
+// DefineParameterType registers p under its name, replacing any existing
+// ParameterType with the same name.
+func (r *ParameterTypeRegistry) DefineParameterType(p *ParameterType) {
+	r.parameterTypesByName[p.name] = p
+}
+
+// LookupByTypeName returns the registered ParameterType named name, or nil.
+func (r *ParameterTypeRegistry) LookupByTypeName(name string) *ParameterType {
+	return r.parameterTypesByName[name]
+}
+
+// SetRegexEngine changes the RegexEngine NewCucumberExpression compiles with
+// by default for expressions built from this registry.
+func (r *ParameterTypeRegistry) SetRegexEngine(engine RegexEngine) {
+	r.defaultEngine = engine
+}
+
+func (r *ParameterTypeRegistry) regexEngine() RegexEngine {
+	if r.defaultEngine == nil {
+		return defaultRegexEngine
+	}
+	return r.defaultEngine
+}
+
+// EnableExtendedAlternations opts this registry's expressions into the
+// "!word" negation and "(a & b)" conjunction alternatives.
+func (r *ParameterTypeRegistry) EnableExtendedAlternations() {
+	r.extendedAlternations = true
+}
+
+func (r *ParameterTypeRegistry) extendedAlternationsEnabled() bool {
+	return r.extendedAlternations
+}
@@ -0,0 +1,53 @@
+package cucumberexpressions
+
+import "regexp"
+
+// CompiledRegexp is the surface CucumberExpression and TreeRegexp need from a
+// compiled pattern, satisfied by the RE2 adapter re2CompiledRegexp and by
+// adapters for other engines.
+type CompiledRegexp interface {
+	FindSubmatch(s string) []string
+	String() string
+}
+
+// RegexEngine abstracts the regular expression implementation used to compile
+// and match a CucumberExpression's generated pattern.
+type RegexEngine interface {
+	Compile(pattern string) (CompiledRegexp, error)
+}
+
+// re2Engine is the default RegexEngine, backed by Go's standard regexp package.
+type re2Engine struct{}
+
+// NewRE2Engine returns the default RegexEngine.
+func NewRE2Engine() RegexEngine {
+	return re2Engine{}
+}
+
+func (re2Engine) Compile(pattern string) (CompiledRegexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re2CompiledRegexp{re}, nil
+}
+
+// re2CompiledRegexp adapts *regexp.Regexp to CompiledRegexp.
+type re2CompiledRegexp struct {
+	re *regexp.Regexp
+}
+
+func (c re2CompiledRegexp) FindSubmatch(s string) []string {
+	return c.re.FindStringSubmatch(s)
+}
+
+func (c re2CompiledRegexp) String() string {
+	return c.re.String()
+}
+
+// Regexp returns the underlying *regexp.Regexp, e.g. for CucumberExpression.Regexp().
+func (c re2CompiledRegexp) Regexp() *regexp.Regexp {
+	return c.re
+}
+
+var defaultRegexEngine RegexEngine = NewRE2Engine()
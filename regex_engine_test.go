@@ -0,0 +1,82 @@
+package cucumberexpressions
+
+import "testing"
+
+func TestRE2EngineCompile(t *testing.T) {
+	engine := NewRE2Engine()
+
+	compiled, err := engine.Compile(`(\d+) (\w+)`)
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+
+	got := compiled.FindSubmatch("12 cukes")
+	want := []string{"12 cukes", "12", "cukes"}
+	if len(got) != len(want) {
+		t.Fatalf("FindSubmatch = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("FindSubmatch[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if compiled.String() != `(\d+) (\w+)` {
+		t.Errorf("String() = %q, want %q", compiled.String(), `(\d+) (\w+)`)
+	}
+}
+
+func TestRE2EngineCompileInvalidPattern(t *testing.T) {
+	engine := NewRE2Engine()
+
+	if _, err := engine.Compile(`(`); err == nil {
+		t.Fatal("Compile(\"(\") = nil error, want error for unbalanced group")
+	}
+}
+
+func TestRE2EngineCompileNoMatch(t *testing.T) {
+	engine := NewRE2Engine()
+
+	compiled, err := engine.Compile(`^\d+$`)
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+	if got := compiled.FindSubmatch("not a number"); got != nil {
+		t.Errorf("FindSubmatch = %v, want nil", got)
+	}
+}
+
+// countingEngine wraps re2Engine to record how many times it was asked to
+// compile a pattern, proving CucumberExpression actually routes through the
+// RegexEngine passed to NewCucumberExpressionWithEngine rather than always
+// falling back to the default.
+type countingEngine struct {
+	compiles *int
+}
+
+func (e countingEngine) Compile(pattern string) (CompiledRegexp, error) {
+	*e.compiles++
+	return re2Engine{}.Compile(pattern)
+}
+
+func TestNewCucumberExpressionWithEngineUsesGivenEngine(t *testing.T) {
+	compiles := 0
+	engine := countingEngine{compiles: &compiles}
+	registry := NewParameterTypeRegistry()
+
+	e, err := NewCucumberExpressionWithEngine("I have {int} cukes", registry, engine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compiles != 1 {
+		t.Fatalf("engine.Compile called %d times, want 1", compiles)
+	}
+
+	args, err := e.Match("I have 42 cukes")
+	if err != nil || args == nil {
+		t.Fatalf("Match = (%v, %v), want a match", args, err)
+	}
+	if got := args[0].GetValue(nil); got != 42 {
+		t.Errorf("got %v, want 42", got)
+	}
+}
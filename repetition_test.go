@@ -0,0 +1,85 @@
+package cucumberexpressions
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestParseRepetitionModifier(t *testing.T) {
+	tests := []struct {
+		text    string
+		name    string
+		sep     string
+		ok      bool
+		wantErr bool
+	}{
+		{text: "int+", name: "int", sep: defaultRepetitionSeparatorPattern, ok: true},
+		{text: "int, ", name: "int", sep: defaultRepetitionSeparatorPattern, ok: true},
+		{text: "int;;", name: "int", sep: "", ok: true},
+		{text: "int;|;", name: "int", sep: regexp.QuoteMeta("|"), ok: true},
+		{text: "int", ok: false},
+		{text: "int;foo", ok: false, wantErr: true},
+		{text: "int;foo;bar", ok: false, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		name, sep, ok, err := parseRepetitionModifier(tt.text)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseRepetitionModifier(%q) error = nil, want error", tt.text)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRepetitionModifier(%q) unexpected error: %v", tt.text, err)
+			continue
+		}
+		if ok != tt.ok {
+			t.Errorf("parseRepetitionModifier(%q) ok = %v, want %v", tt.text, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if name != tt.name || sep != tt.sep {
+			t.Errorf("parseRepetitionModifier(%q) = (%q, %q), want (%q, %q)", tt.text, name, sep, tt.name, tt.sep)
+		}
+	}
+}
+
+func TestBuildNonCapturingRegexp(t *testing.T) {
+	single := []*regexp.Regexp{regexp.MustCompile(`\d+`)}
+	if got, want := buildNonCapturingRegexp(single), `(?:\d+)`; got != want {
+		t.Errorf("buildNonCapturingRegexp(single) = %q, want %q", got, want)
+	}
+
+	multi := []*regexp.Regexp{regexp.MustCompile(`\d+`), regexp.MustCompile(`[a-z]+`)}
+	if got, want := buildNonCapturingRegexp(multi), `(?:(?:\d+)|(?:[a-z]+))`; got != want {
+		t.Errorf("buildNonCapturingRegexp(multi) = %q, want %q", got, want)
+	}
+}
+
+// TestRepeatedParameterCapturesWholeRunNotSplitIntoSlice pins down that
+// {name+} matches and captures the full repeated run as one string; the run
+// is not split into a []T of individually transformed elements.
+func TestRepeatedParameterCapturesWholeRunNotSplitIntoSlice(t *testing.T) {
+	registry := NewParameterTypeRegistry()
+
+	e, err := NewCucumberExpression("I have {word+} cukes", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args, err := e.Match("I have a,b,c cukes")
+	if err != nil || args == nil {
+		t.Fatalf("Match = (%v, %v), want a match", args, err)
+	}
+
+	got := args[0].GetValue(nil)
+	if _, isSlice := got.([]string); isSlice {
+		t.Fatal("GetValue returned a []string; update this test, {name+} now splits into a slice")
+	}
+	if got != "a,b,c" {
+		t.Errorf("GetValue() = %q, want the whole unsplit capture %q", got, "a,b,c")
+	}
+}
@@ -0,0 +1,36 @@
+package cucumberexpressions
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Transformer converts a single matched string into a typeHint-shaped value,
+// used for anonymous inline-constraint parameters that have no registered
+// ParameterType of their own.
+type Transformer interface {
+	Transform(value string, typeHint reflect.Type) (interface{}, error)
+}
+
+type defaultTransformerImpl struct{}
+
+func (defaultTransformerImpl) Transform(value string, typeHint reflect.Type) (interface{}, error) {
+	switch typeHint.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, typeHint.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(n).Convert(typeHint).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, typeHint.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(f).Convert(typeHint).Interface(), nil
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
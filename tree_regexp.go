@@ -0,0 +1,36 @@
+package cucumberexpressions
+
+import "regexp"
+
+// TreeRegexp wraps a compiled pattern behind a RegexEngine-agnostic Match,
+// returning one matched string per capture group.
+type TreeRegexp struct {
+	compiled CompiledRegexp
+}
+
+// NewTreeRegexp wraps a standard library *regexp.Regexp.
+func NewTreeRegexp(re *regexp.Regexp) *TreeRegexp {
+	return &TreeRegexp{compiled: re2CompiledRegexp{re: re}}
+}
+
+// NewTreeRegexpWithCompiledRegexp wraps a CompiledRegexp from any RegexEngine.
+func NewTreeRegexpWithCompiledRegexp(compiled CompiledRegexp) *TreeRegexp {
+	return &TreeRegexp{compiled: compiled}
+}
+
+// Match returns the capture groups for text, or nil if it doesn't match.
+func (t *TreeRegexp) Match(text string) []string {
+	m := t.compiled.FindSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	return m[1:]
+}
+
+// Regexp returns the underlying *regexp.Regexp, for engines that have one.
+func (t *TreeRegexp) Regexp() *regexp.Regexp {
+	if withRegexp, ok := t.compiled.(interface{ Regexp() *regexp.Regexp }); ok {
+		return withRegexp.Regexp()
+	}
+	return regexp.MustCompile(t.compiled.String())
+}